@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"netprobe/pkg/probe"
+
+	"gopkg.in/yaml.v3"
 )
 
 func main() {
@@ -19,6 +21,11 @@ func main() {
 	cmd := os.Args[1]
 	args := os.Args[2:]
 
+	if cmd == "batch" {
+		runBatchCommand(args)
+		return
+	}
+
 	var res probe.Result
 	var err error
 
@@ -45,6 +52,7 @@ func main() {
 		target := fs.String("target", "", "target host or ip")
 		maxHops := fs.Int("max-hops", 30, "max hops")
 		timeout := fs.Int("timeout", 60, "timeout seconds")
+		enrichASN := fs.Bool("enrich-asn", false, "resolve each hop IP to ASN/org via Team Cymru")
 		_ = fs.Parse(args)
 		if *target == "" {
 			err = fmt.Errorf("target is required")
@@ -54,6 +62,7 @@ func main() {
 			Target:     *target,
 			MaxHops:    *maxHops,
 			TimeoutSec: *timeout,
+			EnrichASN:  *enrichASN,
 			Tool:       "network.traceroute",
 		})
 
@@ -63,6 +72,7 @@ func main() {
 		count := fs.Int("count", 10, "probe count")
 		reportCycles := fs.Int("report-cycles", 10, "report cycles")
 		timeout := fs.Int("timeout", 60, "timeout seconds")
+		enrichASN := fs.Bool("enrich-asn", false, "resolve each hop IP to ASN/org via Team Cymru")
 		_ = fs.Parse(args)
 		if *target == "" {
 			err = fmt.Errorf("target is required")
@@ -73,6 +83,7 @@ func main() {
 			Count:        *count,
 			ReportCycles: *reportCycles,
 			TimeoutSec:   *timeout,
+			EnrichASN:    *enrichASN,
 			Tool:         "network.mtr",
 		})
 
@@ -122,21 +133,81 @@ func main() {
 		caCert := fs.String("ca-cert", "", "CA certificate path")
 		clientCert := fs.String("client-cert", "", "client certificate path")
 		clientKey := fs.String("client-key", "", "client key path")
+		outputCertDir := fs.String("output-cert-dir", "", "directory to accumulate observed certs as <sha256>.pem/.json")
 		_ = fs.Parse(args)
 		if *host == "" || *port == 0 {
 			err = fmt.Errorf("host and port are required")
 			break
 		}
 		res = probe.TLSProbe(probe.TLSOptions{
+			Host:          *host,
+			Port:          *port,
+			ServerName:    *serverName,
+			TimeoutSec:    *timeout,
+			Insecure:      *insecure,
+			CACert:        *caCert,
+			ClientCert:    *clientCert,
+			ClientKey:     *clientKey,
+			OutputCertDir: *outputCertDir,
+			Tool:          "network.tls",
+		})
+
+	case "tls-probe-sni":
+		fs := flag.NewFlagSet("tls-probe-sni", flag.ExitOnError)
+		host := fs.String("host", "", "target host")
+		port := fs.Int("port", 443, "target port")
+		timeout := fs.Int("timeout", 10, "timeout seconds")
+		insecure := fs.Bool("insecure", false, "skip certificate verification")
+		sniList := multiString{}
+		fs.Var(&sniList, "sni", "candidate SNI value (can repeat)")
+		_ = fs.Parse(args)
+		if *host == "" || *port == 0 || len(sniList) == 0 {
+			err = fmt.Errorf("host, port and at least one --sni are required")
+			break
+		}
+		res = probe.TLSSNIProbe(probe.SNIProbeOptions{
 			Host:       *host,
 			Port:       *port,
-			ServerName: *serverName,
+			SNIs:       []string(sniList),
 			TimeoutSec: *timeout,
 			Insecure:   *insecure,
-			CACert:     *caCert,
-			ClientCert: *clientCert,
-			ClientKey:  *clientKey,
-			Tool:       "network.tls",
+			Tool:       "network.tls-probe-sni",
+		})
+
+	case "tls-ciphers":
+		fs := flag.NewFlagSet("tls-ciphers", flag.ExitOnError)
+		host := fs.String("host", "", "target host")
+		port := fs.Int("port", 443, "target port")
+		timeout := fs.Int("timeout", 10, "timeout seconds")
+		parallel := fs.Int("parallel", 8, "bounded worker pool size")
+		_ = fs.Parse(args)
+		if *host == "" || *port == 0 {
+			err = fmt.Errorf("host and port are required")
+			break
+		}
+		res = probe.TLSEnumerate(probe.TLSEnumerateOptions{
+			Host:       *host,
+			Port:       *port,
+			TimeoutSec: *timeout,
+			Parallel:   *parallel,
+			Tool:       "network.tls-ciphers",
+		})
+
+	case "jarm":
+		fs := flag.NewFlagSet("jarm", flag.ExitOnError)
+		host := fs.String("host", "", "target host")
+		port := fs.Int("port", 443, "target port")
+		timeout := fs.Int("timeout", 10, "timeout seconds")
+		_ = fs.Parse(args)
+		if *host == "" || *port == 0 {
+			err = fmt.Errorf("host and port are required")
+			break
+		}
+		res = probe.JARMProbe(probe.JARMOptions{
+			Host:       *host,
+			Port:       *port,
+			TimeoutSec: *timeout,
+			Tool:       "network.jarm",
 		})
 
 	case "http":
@@ -150,6 +221,11 @@ func main() {
 		headersJSON := fs.String("headers", "", "headers as JSON object, e.g. {\"User-Agent\":\"netprobe\"}")
 		headerKVs := multiString{}
 		fs.Var(&headerKVs, "header", "single header in 'Key: Value' format (can repeat)")
+		protocol := fs.String("protocol", "", "force transport protocol: h1, h2, or h3 (default: auto)")
+		expectHeaderKVs := multiString{}
+		fs.Var(&expectHeaderKVs, "expect-header", "assert a response header matches a regex, 'Name: pattern' (can repeat)")
+		expectJSONPathKVs := multiString{}
+		fs.Var(&expectJSONPathKVs, "expect-jsonpath", "assert a JSONPath value matches a regex, '$.path: pattern' (can repeat)")
 		_ = fs.Parse(args)
 		if *url == "" {
 			err = fmt.Errorf("url is required")
@@ -168,14 +244,31 @@ func main() {
 				headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
 			}
 		}
+		expectHeader := map[string]string{}
+		for _, h := range expectHeaderKVs {
+			parts := strings.SplitN(h, ":", 2)
+			if len(parts) == 2 {
+				expectHeader[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+			}
+		}
+		expectJSONPath := map[string]string{}
+		for _, h := range expectJSONPathKVs {
+			parts := strings.SplitN(h, ":", 2)
+			if len(parts) == 2 {
+				expectJSONPath[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+			}
+		}
 		res = probe.HTTPProbe(probe.HTTPOptions{
 			URL:            *url,
 			Method:         *method,
 			Headers:        headers,
 			Body:           *body,
 			TimeoutSec:     *timeout,
+			Protocol:       *protocol,
 			ExpectStatus:   *expectStatus,
 			ExpectContains: *expectContains,
+			ExpectHeader:   expectHeader,
+			ExpectJSONPath: expectJSONPath,
 			Tool:           "network.http",
 		})
 
@@ -194,6 +287,53 @@ func main() {
 	printJSON(res)
 }
 
+// runBatchCommand 读取 BatchSpec YAML 文件并执行 `netprobe batch`，按 --ndjson 决定输出形式。
+func runBatchCommand(args []string) {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	file := fs.String("file", "", "path to batch job spec (YAML)")
+	ndjson := fs.Bool("ndjson", false, "stream one JSON Result per line instead of a single JSON array")
+	parallel := fs.Int("parallel", 0, "override worker pool size from the spec")
+	_ = fs.Parse(args)
+
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "--file is required")
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read batch file failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	var spec probe.BatchSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		fmt.Fprintf(os.Stderr, "parse batch file failed: %v\n", err)
+		os.Exit(1)
+	}
+	if *parallel > 0 {
+		spec.Parallel = *parallel
+	}
+
+	if !*ndjson {
+		printBatchResults(probe.RunBatch(spec))
+		return
+	}
+
+	out := make(chan probe.Result, 16)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		enc := json.NewEncoder(os.Stdout)
+		for r := range out {
+			_ = enc.Encode(r)
+		}
+	}()
+	probe.RunBatchStream(spec, out)
+	close(out)
+	<-done
+}
+
 type multiString []string
 
 func (m *multiString) String() string {
@@ -213,16 +353,29 @@ func printJSON(res probe.Result) {
 	fmt.Println(string(data))
 }
 
+func printBatchResults(results []probe.Result) {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "marshal result failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
+
 func usage() string {
 	return `netprobe <subcommand> [options]
 
 subcommands:
   ping         --target <host> [--count 4] [--timeout 10]
-  trace        --target <host> [--max-hops 30] [--timeout 60]
-  mtr          --target <host> [--count 10] [--report-cycles 10] [--timeout 60]
+  trace        --target <host> [--max-hops 30] [--timeout 60] [--enrich-asn]
+  mtr          --target <host> [--count 10] [--report-cycles 10] [--timeout 60] [--enrich-asn]
   nslookup     --target <domain> [--record-type A] [--timeout 10]
   tcp          --host <host> --port <port> [--timeout 10] [--retry 0]
-  tls          --host <host> [--port 443] [--server-name <sni>] [--timeout 10] [--insecure] [--ca-cert path] [--client-cert path --client-key path]
-  http         --url <url> [--method GET] [--timeout 15] [--expect-status <code>] [--expect-contains <str>] [--body <data>] [--headers <json>] [--header "K: V"]
+  tls          --host <host> [--port 443] [--server-name <sni>] [--timeout 10] [--insecure] [--ca-cert path] [--client-cert path --client-key path] [--output-cert-dir dir]
+  tls-probe-sni --host <host> [--port 443] --sni <name> [--sni <name> ...] [--timeout 10] [--insecure]
+  tls-ciphers  --host <host> [--port 443] [--timeout 10] [--parallel 8]
+  jarm         --host <host> [--port 443] [--timeout 10]
+  batch        --file jobs.yaml [--ndjson] [--parallel N]
+  http         --url <url> [--method GET] [--timeout 15] [--protocol h1|h2|h3] [--expect-status <code>] [--expect-contains <str>] [--expect-header "Name: regex"] [--expect-jsonpath "$.path: regex"] [--body <data>] [--headers <json>] [--header "K: V"]
 `
 }
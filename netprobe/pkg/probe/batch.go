@@ -0,0 +1,315 @@
+package probe
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BatchJob 描述批量任务中的单个探测作业。Type 决定调用哪个探测器（ping/tcp/tls/http/nslookup/mtr/traceroute/jarm）。
+// Target 支持 CIDR（展开为多个 host 作业）与主机名（若解析出多个 A/AAAA 地址则按每个 IP 各生成一个作业）；
+// PortRange 形如 "80-85"，与 Port 互斥，展开为多个端口各一个作业。
+type BatchJob struct {
+	Name       string            `yaml:"name" json:"name"`
+	Type       string            `yaml:"type" json:"type"`
+	Target     string            `yaml:"target,omitempty" json:"target,omitempty"`
+	Host       string            `yaml:"host,omitempty" json:"host,omitempty"`
+	Port       int               `yaml:"port,omitempty" json:"port,omitempty"`
+	PortRange  string            `yaml:"port_range,omitempty" json:"port_range,omitempty"`
+	URL        string            `yaml:"url,omitempty" json:"url,omitempty"`
+	Params     map[string]string `yaml:"params,omitempty" json:"params,omitempty"`
+	Tags       []string          `yaml:"tags,omitempty" json:"tags,omitempty"`
+	TimeoutSec int               `yaml:"timeout_sec,omitempty" json:"timeout_sec,omitempty"`
+	Retry      int               `yaml:"retry,omitempty" json:"retry,omitempty"`
+	BackoffMs  int               `yaml:"backoff_ms,omitempty" json:"backoff_ms,omitempty"`
+	DependsOn  []string          `yaml:"depends_on,omitempty" json:"depends_on,omitempty"`
+}
+
+// BatchSpec 是 `netprobe batch --file jobs.yaml` 读取的声明式作业清单。
+type BatchSpec struct {
+	Parallel int        `yaml:"parallel,omitempty" json:"parallel,omitempty"`
+	Jobs     []BatchJob `yaml:"jobs" json:"jobs"`
+}
+
+// expandedJob 是目标展开（CIDR/端口范围/多 A 记录）之后的具体作业，仍带着原始作业名用于 depends_on 判定。
+type expandedJob struct {
+	parentName string
+	job        BatchJob
+}
+
+// RunBatch 展开 spec 中的作业目标，按 depends_on 形成的依赖波次并发执行，返回全部展开后作业的结果。
+func RunBatch(spec BatchSpec) []Result {
+	out := make(chan Result, 16)
+	go func() {
+		RunBatchStream(spec, out)
+		close(out)
+	}()
+
+	var results []Result
+	for r := range out {
+		results = append(results, r)
+	}
+	return results
+}
+
+// RunBatchStream 与 RunBatch 行为一致，但把每个作业完成的 Result 实时发往 out，
+// 供 `--ndjson` 场景边执行边输出；调用方负责消费 out 直至其自然耗尽（函数返回即不再写入）。
+func RunBatchStream(spec BatchSpec, out chan<- Result) {
+	parallel := spec.Parallel
+	if parallel <= 0 {
+		parallel = 4
+	}
+
+	expanded := expandBatchJobs(spec.Jobs)
+
+	// 依赖是否满足按"作业名 + 展开后的具体目标（host:port）"关联：一个作业只关心
+	// 依赖作业在相同目标上的结果，不受该依赖名下其它目标（同一 CIDR/端口范围展开出
+	// 的其它实例）成败的影响。
+	var mu sync.Mutex
+	successByTarget := map[string]bool{}
+	doneByTarget := map[string]bool{}
+	pending := map[string]*expandedJob{}
+	for i := range expanded {
+		pending[jobKey(expanded[i])] = &expanded[i]
+	}
+
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	isReady := func(ej expandedJob) bool {
+		for _, dep := range ej.job.DependsOn {
+			mu.Lock()
+			done := doneByTarget[depKey(dep, ej.job.Target)]
+			ok := successByTarget[depKey(dep, ej.job.Target)]
+			mu.Unlock()
+			if !done || !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	hasFailedDep := func(ej expandedJob) bool {
+		for _, dep := range ej.job.DependsOn {
+			mu.Lock()
+			done := doneByTarget[depKey(dep, ej.job.Target)]
+			ok := successByTarget[depKey(dep, ej.job.Target)]
+			mu.Unlock()
+			if done && !ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	remaining := len(pending)
+	for remaining > 0 {
+		progressed := false
+		for key, ejPtr := range pending {
+			ej := *ejPtr
+			if hasFailedDep(ej) {
+				delete(pending, key)
+				remaining--
+				progressed = true
+				res := Result{
+					Success: false,
+					Tool:    "network." + ej.job.Type,
+					Target:  ej.job.Target,
+					Error:   "skipped: dependency failed",
+				}
+				recordBatchOutcome(&mu, successByTarget, doneByTarget, ej.parentName, ej.job.Target, false)
+				out <- res
+				continue
+			}
+			if !isReady(ej) {
+				continue
+			}
+			delete(pending, key)
+			remaining--
+			progressed = true
+
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(ej expandedJob) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				res := runBatchJobWithRetry(ej.job)
+				recordBatchOutcome(&mu, successByTarget, doneByTarget, ej.parentName, ej.job.Target, res.Success)
+				out <- res
+			}(ej)
+		}
+		if !progressed {
+			// 剩余作业相互依赖却无法就绪（例如引用了不存在的作业名），全部标记为跳过。
+			for key, ejPtr := range pending {
+				ej := *ejPtr
+				delete(pending, key)
+				out <- Result{
+					Success: false,
+					Tool:    "network." + ej.job.Type,
+					Target:  ej.job.Target,
+					Error:   "skipped: unresolved dependency",
+				}
+			}
+			break
+		}
+		wg.Wait()
+	}
+	wg.Wait()
+}
+
+func recordBatchOutcome(mu *sync.Mutex, successByTarget, doneByTarget map[string]bool, name, target string, success bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	k := depKey(name, target)
+	successByTarget[k] = success
+	doneByTarget[k] = true
+}
+
+// depKey 把"被依赖的作业名"与"具体展开目标（host:port 等）"关联成唯一 key，
+// 使 depends_on 按目标而非作业名整体聚合成败。
+func depKey(name, target string) string {
+	return name + "|" + target
+}
+
+func jobKey(ej expandedJob) string {
+	return fmt.Sprintf("%s|%s|%d|%s", ej.parentName, ej.job.Host, ej.job.Port, ej.job.Target)
+}
+
+// expandBatchJobs 把声明式目标（CIDR、端口范围、多 A 记录主机名）展开为一批具体作业。
+func expandBatchJobs(jobs []BatchJob) []expandedJob {
+	var out []expandedJob
+	for _, job := range jobs {
+		hosts := expandTargetHosts(job)
+		ports := expandPortRange(job)
+
+		if len(ports) == 0 {
+			for _, h := range hosts {
+				j := job
+				j.Host = h
+				j.Target = h
+				out = append(out, expandedJob{parentName: job.Name, job: j})
+			}
+			continue
+		}
+
+		for _, h := range hosts {
+			for _, p := range ports {
+				j := job
+				j.Host = h
+				j.Port = p
+				j.Target = fmt.Sprintf("%s:%d", h, p)
+				out = append(out, expandedJob{parentName: job.Name, job: j})
+			}
+		}
+	}
+	return out
+}
+
+// expandTargetHosts 把 job.Target/job.Host 展开为具体主机列表：CIDR 展开为网段内的每个地址（含网络地址与广播地址），
+// 否则按主机名解析出的全部 A/AAAA 地址各生成一项；解析失败或为纯 IP/主机名时原样返回单项。
+func expandTargetHosts(job BatchJob) []string {
+	raw := job.Host
+	if raw == "" {
+		raw = job.Target
+	}
+	if raw == "" {
+		return []string{""}
+	}
+
+	if ip, ipNet, err := net.ParseCIDR(raw); err == nil {
+		var hosts []string
+		for cur := ip.Mask(ipNet.Mask); ipNet.Contains(cur); incIP(cur) {
+			hosts = append(hosts, cur.String())
+		}
+		return hosts
+	}
+
+	if net.ParseIP(raw) != nil {
+		return []string{raw}
+	}
+
+	if ips, err := net.LookupHost(raw); err == nil && len(ips) > 1 {
+		return ips
+	}
+
+	return []string{raw}
+}
+
+func incIP(ip net.IP) {
+	for i := len(ip) - 1; i >= 0; i-- {
+		ip[i]++
+		if ip[i] != 0 {
+			break
+		}
+	}
+}
+
+// expandPortRange 解析 "80-85" 形式的端口范围；若 PortRange 为空则返回单一 Port（非 0 时）。
+func expandPortRange(job BatchJob) []int {
+	if job.PortRange == "" {
+		if job.Port != 0 {
+			return []int{job.Port}
+		}
+		return nil
+	}
+	parts := strings.SplitN(job.PortRange, "-", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	start, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
+	end, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err1 != nil || err2 != nil || start > end {
+		return nil
+	}
+	var ports []int
+	for p := start; p <= end; p++ {
+		ports = append(ports, p)
+	}
+	return ports
+}
+
+// runBatchJobWithRetry 按 job.Retry/BackoffMs 执行带退避的重试，返回最后一次的结果。
+func runBatchJobWithRetry(job BatchJob) Result {
+	var res Result
+	for attempt := 0; attempt <= job.Retry; attempt++ {
+		res = dispatchBatchJob(job)
+		if res.Success {
+			return res
+		}
+		if attempt < job.Retry && job.BackoffMs > 0 {
+			time.Sleep(time.Duration(job.BackoffMs) * time.Millisecond)
+		}
+	}
+	return res
+}
+
+// dispatchBatchJob 把一个展开后的作业路由到对应探测器。
+func dispatchBatchJob(job BatchJob) Result {
+	switch job.Type {
+	case "ping":
+		return Ping(PingOptions{Target: job.Target, TimeoutSec: job.TimeoutSec, Tool: "network.ping"})
+	case "tcp":
+		return TCPProbe(TCPOptions{Host: job.Host, Port: job.Port, TimeoutSec: job.TimeoutSec, Tool: "network.tcp"})
+	case "tls":
+		return TLSProbe(TLSOptions{Host: job.Host, Port: job.Port, ServerName: job.Params["server_name"], TimeoutSec: job.TimeoutSec, Tool: "network.tls"})
+	case "http":
+		url := job.URL
+		if url == "" {
+			url = job.Target
+		}
+		return HTTPProbe(HTTPOptions{URL: url, Method: job.Params["method"], TimeoutSec: job.TimeoutSec, ExpectStatus: atoi(job.Params["expect_status"]), ExpectContains: job.Params["expect_contains"], Tool: "network.http"})
+	case "nslookup":
+		return Nslookup(NslookupOptions{Target: job.Target, RecordType: job.Params["record_type"], TimeoutSec: job.TimeoutSec, Tool: "network.nslookup"})
+	case "mtr":
+		return Mtr(MtrOptions{Target: job.Target, TimeoutSec: job.TimeoutSec, Tool: "network.mtr"})
+	case "traceroute", "trace":
+		return Traceroute(TraceOptions{Target: job.Target, TimeoutSec: job.TimeoutSec, Tool: "network.traceroute"})
+	case "jarm":
+		return JARMProbe(JARMOptions{Host: job.Host, Port: job.Port, TimeoutSec: job.TimeoutSec, Tool: "network.jarm"})
+	default:
+		return Result{Success: false, Tool: "network." + job.Type, Target: job.Target, Error: fmt.Sprintf("unknown batch job type: %s", job.Type)}
+	}
+}
@@ -0,0 +1,228 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MtrHop 是 mtr -r 输出中单跳的结构化数据，供下游（如 Python MCP 层）直接消费。
+type MtrHop struct {
+	Hop      int     `json:"hop"`
+	Host     string  `json:"host,omitempty"`
+	IP       string  `json:"ip,omitempty"`
+	LossPct  float64 `json:"loss_pct"`
+	Sent     int     `json:"sent"`
+	LastMs   float64 `json:"last_ms"`
+	AvgMs    float64 `json:"avg_ms"`
+	BestMs   float64 `json:"best_ms"`
+	WorstMs  float64 `json:"worst_ms"`
+	StdDevMs float64 `json:"stddev_ms"`
+	ASN      string  `json:"asn,omitempty"`
+	ASNOrg   string  `json:"asn_org,omitempty"`
+}
+
+// TraceHop 是 traceroute/tracert 单跳的结构化数据。
+type TraceHop struct {
+	Hop    int       `json:"hop"`
+	IP     string    `json:"ip,omitempty"`
+	RTTMs  []float64 `json:"rtt_ms,omitempty"`
+	ASN    string    `json:"asn,omitempty"`
+	ASNOrg string    `json:"asn_org,omitempty"`
+}
+
+// mtrReportLine 匹配 `mtr -r -n` 报表中的一跳，例如：
+//
+//	  1.|-- 192.168.1.1      0.0%    10    0.5   0.6   0.4   1.2   0.2
+var mtrReportLine = regexp.MustCompile(`^\s*(\d+)\.\|--\s+(\S+)\s+([\d.]+)%\s+(\d+)\s+([\d.]+)\s+([\d.]+)\s+([\d.]+)\s+([\d.]+)\s+([\d.]+)`)
+
+// parseMtrHops 把 `mtr -r -n` 的文本输出解析为结构化的每跳数据。
+func parseMtrHops(output string) []MtrHop {
+	var hops []MtrHop
+	for _, line := range strings.Split(output, "\n") {
+		m := mtrReportLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		hop := MtrHop{
+			Hop:      atoi(m[1]),
+			LossPct:  atof(m[3]),
+			Sent:     atoi(m[4]),
+			LastMs:   atof(m[5]),
+			AvgMs:    atof(m[6]),
+			BestMs:   atof(m[7]),
+			WorstMs:  atof(m[8]),
+			StdDevMs: atof(m[9]),
+		}
+		if net.ParseIP(m[2]) != nil {
+			hop.IP = m[2]
+		} else {
+			hop.Host = m[2]
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+// unixTraceLine 匹配类 Unix traceroute -n 的一跳，例如：
+//
+//	 1  192.168.1.1  0.543 ms  0.502 ms  0.498 ms
+var unixTraceLine = regexp.MustCompile(`^\s*(\d+)\s+(\*|\S+)((?:\s+[\d.]+\s+ms|\s+\*)*)`)
+
+// unixTraceRTT 从行尾提取每次探测的 RTT（ms）。
+var unixTraceRTT = regexp.MustCompile(`([\d.]+)\s+ms`)
+
+// windowsTraceLine 匹配 tracert 的一跳，例如：
+//
+//	  1    <1 ms    <1 ms    <1 ms  192.168.1.1
+var windowsTraceLine = regexp.MustCompile(`^\s*(\d+)\s+((?:[<\d]+\s*ms|\*)\s+){1,3}(\S+)\s*$`)
+var windowsTraceRTT = regexp.MustCompile(`<?(\d+)\s*ms`)
+
+// parseTraceHops 解析 traceroute（Unix）或 tracert（Windows）的文本输出。
+func parseTraceHops(output string, windows bool) []TraceHop {
+	var hops []TraceHop
+	for _, line := range strings.Split(output, "\n") {
+		if windows {
+			m := windowsTraceLine.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			hop := TraceHop{Hop: atoi(m[1])}
+			if ip := strings.TrimSpace(m[len(m)-1]); net.ParseIP(ip) != nil {
+				hop.IP = ip
+			}
+			for _, rtt := range windowsTraceRTT.FindAllStringSubmatch(line, -1) {
+				hop.RTTMs = append(hop.RTTMs, atof(rtt[1]))
+			}
+			hops = append(hops, hop)
+			continue
+		}
+
+		m := unixTraceLine.FindStringSubmatch(line)
+		if m == nil || m[2] == "*" {
+			continue
+		}
+		hop := TraceHop{Hop: atoi(m[1])}
+		if net.ParseIP(m[2]) != nil {
+			hop.IP = m[2]
+		}
+		for _, rtt := range unixTraceRTT.FindAllStringSubmatch(m[3], -1) {
+			hop.RTTMs = append(hop.RTTMs, atof(rtt[1]))
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func atof(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+// asnCache 是进程内的 IP -> ASN/组织 解析结果缓存，避免批量跳数重复查询 Cymru。
+var asnCache sync.Map // map[string]asnLookupResult
+
+type asnLookupResult struct {
+	asn string
+	org string
+	err error
+}
+
+// lookupASN 通过 Team Cymru 的 DNS whois 服务解析 ip 所属的 ASN 与组织名，结果在进程内缓存。
+func lookupASN(ctx context.Context, ip string) (string, string, error) {
+	if cached, ok := asnCache.Load(ip); ok {
+		r := cached.(asnLookupResult)
+		return r.asn, r.org, r.err
+	}
+
+	asn, org, err := queryCymruASN(ctx, ip)
+	asnCache.Store(ip, asnLookupResult{asn: asn, org: org, err: err})
+	return asn, org, err
+}
+
+func queryCymruASN(ctx context.Context, ip string) (string, string, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return "", "", nil
+	}
+	reversed, err := reverseIPOctets(parsed)
+	if err != nil {
+		return "", "", err
+	}
+
+	resolver := &net.Resolver{PreferGo: true}
+	originTXT, err := resolver.LookupTXT(ctx, reversed+".origin.asn.cymru.com")
+	if err != nil || len(originTXT) == 0 {
+		return "", "", err
+	}
+	// 格式："15169 | 8.8.8.0/24 | US | arin | 1992-12-01"
+	fields := strings.Split(originTXT[0], "|")
+	if len(fields) == 0 {
+		return "", "", nil
+	}
+	asn := strings.TrimSpace(fields[0])
+	if asn == "" {
+		return "", "", nil
+	}
+
+	org := ""
+	if nameTXT, err := resolver.LookupTXT(ctx, "AS"+asn+".asn.cymru.com"); err == nil && len(nameTXT) > 0 {
+		// 格式："15169 | US | arin | 2000-03-30 | GOOGLE, US"
+		nameFields := strings.Split(nameTXT[0], "|")
+		if len(nameFields) >= 5 {
+			org = strings.TrimSpace(nameFields[4])
+		}
+	}
+
+	return asn, org, nil
+}
+
+func reverseIPOctets(ip net.IP) (string, error) {
+	v4 := ip.To4()
+	if v4 == nil {
+		return "", errors.New("ASN enrichment only supports IPv4 hops")
+	}
+	return strconv.Itoa(int(v4[3])) + "." + strconv.Itoa(int(v4[2])) + "." + strconv.Itoa(int(v4[1])) + "." + strconv.Itoa(int(v4[0])), nil
+}
+
+// enrichMtrASN 为一批 mtr 跳原地补充 ASN/组织信息，timeoutSec 是整批 DNS 查询共用的总超时。
+func enrichMtrASN(hops []MtrHop, timeoutSec int) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSec)*time.Second)
+	defer cancel()
+	for i := range hops {
+		if hops[i].IP == "" {
+			continue
+		}
+		asn, org, err := lookupASN(ctx, hops[i].IP)
+		if err == nil {
+			hops[i].ASN = asn
+			hops[i].ASNOrg = org
+		}
+	}
+}
+
+// enrichTraceASN 为一批 traceroute 跳原地补充 ASN/组织信息。
+func enrichTraceASN(hops []TraceHop, timeoutSec int) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSec)*time.Second)
+	defer cancel()
+	for i := range hops {
+		if hops[i].IP == "" {
+			continue
+		}
+		asn, org, err := lookupASN(ctx, hops[i].IP)
+		if err == nil {
+			hops[i].ASN = asn
+			hops[i].ASNOrg = org
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package probe
+
+import "testing"
+
+func TestParseMtrHops(t *testing.T) {
+	cases := []struct {
+		name string
+		line string
+		want *MtrHop
+	}{
+		{
+			name: "single digit hop",
+			line: "  1.|-- 192.168.1.1      0.0%    10    0.5   0.6   0.4   1.2   0.2",
+			want: &MtrHop{Hop: 1, IP: "192.168.1.1", LossPct: 0.0, Sent: 10, LastMs: 0.5, AvgMs: 0.6, BestMs: 0.4, WorstMs: 1.2, StdDevMs: 0.2},
+		},
+		{
+			name: "two digit hop number must not be truncated",
+			line: " 12.|-- some-host.net         0.0%    10    0.5   0.6   0.4   1.2   0.2",
+			want: &MtrHop{Hop: 12, Host: "some-host.net", LossPct: 0.0, Sent: 10, LastMs: 0.5, AvgMs: 0.6, BestMs: 0.4, WorstMs: 1.2, StdDevMs: 0.2},
+		},
+		{
+			name: "hop number above twenty",
+			line: " 23.|-- 10.0.0.1              0.0%    10    0.5   0.6   0.4   1.2   0.2",
+			want: &MtrHop{Hop: 23, IP: "10.0.0.1", LossPct: 0.0, Sent: 10, LastMs: 0.5, AvgMs: 0.6, BestMs: 0.4, WorstMs: 1.2, StdDevMs: 0.2},
+		},
+		{
+			name: "header line does not match",
+			line: "HOST: example                     Loss%   Snt   Last   Avg  Best  Wrst StDev",
+			want: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			hops := parseMtrHops(tc.line)
+			if tc.want == nil {
+				if len(hops) != 0 {
+					t.Fatalf("expected no hop parsed, got %+v", hops)
+				}
+				return
+			}
+			if len(hops) != 1 {
+				t.Fatalf("expected exactly one hop, got %d: %+v", len(hops), hops)
+			}
+			if hops[0] != *tc.want {
+				t.Fatalf("got %+v, want %+v", hops[0], *tc.want)
+			}
+		})
+	}
+}
+
+func TestParseTraceHopsUnix(t *testing.T) {
+	output := " 1  192.168.1.1  0.543 ms  0.502 ms  0.498 ms\n" +
+		"12  10.0.0.1  1.1 ms  1.2 ms  1.3 ms\n" +
+		"13  *\n"
+
+	hops := parseTraceHops(output, false)
+	if len(hops) != 2 {
+		t.Fatalf("expected 2 hops (the all-* line is skipped), got %d: %+v", len(hops), hops)
+	}
+	if hops[0].Hop != 1 || hops[0].IP != "192.168.1.1" || len(hops[0].RTTMs) != 3 {
+		t.Fatalf("unexpected first hop: %+v", hops[0])
+	}
+	if hops[1].Hop != 12 || hops[1].IP != "10.0.0.1" {
+		t.Fatalf("two-digit hop number truncated: %+v", hops[1])
+	}
+}
+
+func TestParseTraceHopsWindows(t *testing.T) {
+	output := "  1    <1 ms    <1 ms    <1 ms  192.168.1.1\n" +
+		" 11     5 ms     6 ms     5 ms  10.0.0.1\n"
+
+	hops := parseTraceHops(output, true)
+	if len(hops) != 2 {
+		t.Fatalf("expected 2 hops, got %d: %+v", len(hops), hops)
+	}
+	if hops[0].Hop != 1 || hops[0].IP != "192.168.1.1" {
+		t.Fatalf("unexpected first hop: %+v", hops[0])
+	}
+	if hops[1].Hop != 11 || hops[1].IP != "10.0.0.1" {
+		t.Fatalf("two-digit hop number truncated: %+v", hops[1])
+	}
+}
@@ -6,7 +6,11 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/http2"
 )
 
 func HTTPProbe(opts HTTPOptions) Result {
@@ -41,8 +45,12 @@ func HTTPProbe(opts HTTPOptions) Result {
 		req.Header.Set(k, v)
 	}
 
+	timing := newHopTimingRecorder()
+
 	client := &http.Client{
-		Timeout: time.Duration(opts.TimeoutSec) * time.Second,
+		Timeout:       time.Duration(opts.TimeoutSec) * time.Second,
+		Transport:     buildHTTPTransport(opts.Protocol, timing),
+		CheckRedirect: buildCheckRedirect(timing),
 	}
 
 	start := time.Now()
@@ -58,26 +66,37 @@ func HTTPProbe(opts HTTPOptions) Result {
 	}
 	defer resp.Body.Close()
 
-	bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	bodyBytes, _ := io.ReadAll(resp.Body)
 	bodySnippet := string(bodyBytes)
+	if len(bodySnippet) > 4096 {
+		bodySnippet = bodySnippet[:4096]
+	}
+
+	negotiatedALPN := ""
+	if resp.TLS != nil {
+		negotiatedALPN = resp.TLS.NegotiatedProtocol
+	}
 
 	details := map[string]any{
 		"response_headers": resp.Header,
 		"body_snippet":     bodySnippet,
 		"content_length":   resp.ContentLength,
+		"redirect_chain":   timing.chain,
+		"negotiated_alpn":  negotiatedALPN,
+		"proto":            resp.Proto,
 	}
 
-	var expectErr string
+	var expectErrs []string
 	if opts.ExpectStatus != 0 && resp.StatusCode != opts.ExpectStatus {
-		expectErr = fmt.Sprintf("expect status %d, got %d", opts.ExpectStatus, resp.StatusCode)
+		expectErrs = append(expectErrs, fmt.Sprintf("expect status %d, got %d", opts.ExpectStatus, resp.StatusCode))
 	}
-	if opts.ExpectContains != "" && !strings.Contains(bodySnippet, opts.ExpectContains) {
-		if expectErr != "" {
-			expectErr += "; "
-		}
-		expectErr += "response not contains expected substring"
+	if opts.ExpectContains != "" && !strings.Contains(string(bodyBytes), opts.ExpectContains) {
+		expectErrs = append(expectErrs, "response not contains expected substring")
 	}
+	expectErrs = append(expectErrs, checkExpectHeaders(resp.Header, opts.ExpectHeader)...)
+	expectErrs = append(expectErrs, checkExpectJSONPath(bodyBytes, opts.ExpectJSONPath)...)
 
+	expectErr := strings.Join(expectErrs, "; ")
 	success := expectErr == ""
 
 	return Result{
@@ -90,3 +109,87 @@ func HTTPProbe(opts HTTPOptions) Result {
 		Error:      expectErr,
 	}
 }
+
+// hopTimingRecorder 记录跳转链路上每一跳的耗时与元数据，在 CheckRedirect 触发时拼装成 redirect_chain。
+type hopTimingRecorder struct {
+	mu    sync.Mutex
+	start map[*http.Response]time.Time
+	chain []map[string]any
+}
+
+func newHopTimingRecorder() *hopTimingRecorder {
+	return &hopTimingRecorder{start: map[*http.Response]time.Time{}}
+}
+
+// hopTimingTransport 包装底层 RoundTripper，记录每次请求发起的时间点，供 CheckRedirect 计算单跳耗时。
+type hopTimingTransport struct {
+	base http.RoundTripper
+	rec  *hopTimingRecorder
+}
+
+func (t *hopTimingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	hopStart := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	t.rec.mu.Lock()
+	t.rec.start[resp] = hopStart
+	t.rec.mu.Unlock()
+	return resp, err
+}
+
+// buildHTTPTransport 按 Protocol 选择底层传输：h1 关闭 HTTP/2 只走 HTTP/1.1，h2 强制走 golang.org/x/net/http2，
+// h3 通过 quic-go 的 http3.Transport 协商 QUIC/HTTP3，其余（含空值）使用标准库的 h1/h2 自动协商。
+func buildHTTPTransport(protocol string, rec *hopTimingRecorder) http.RoundTripper {
+	var base http.RoundTripper
+	switch protocol {
+	case "h1":
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		// 设置了 DialContext 且不强制 HTTP/2 时，标准库不会自动启用 HTTP/2。
+		t.ForceAttemptHTTP2 = false
+		base = t
+	case "h2":
+		base = &http2.Transport{}
+	case "h3":
+		base = &http3.Transport{}
+	default:
+		base = http.DefaultTransport
+	}
+	return &hopTimingTransport{base: base, rec: rec}
+}
+
+// buildCheckRedirect 返回一个 CheckRedirect，记录每次跳转（URL/状态码/Location/耗时）到 rec.chain。
+// req.Response 是触发本次跳转的上一跳响应（标准库在客户端重定向期间会回填该字段）。
+func buildCheckRedirect(rec *hopTimingRecorder) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		prev := req.Response
+		if prev == nil {
+			return nil
+		}
+		rec.mu.Lock()
+		hopStart, ok := rec.start[prev]
+		rec.mu.Unlock()
+		latencyMs := float64(0)
+		if ok {
+			latencyMs = float64(time.Since(hopStart).Milliseconds())
+		}
+
+		hop := map[string]any{
+			"status_code": prev.StatusCode,
+			"location":    prev.Header.Get("Location"),
+			"latency_ms":  latencyMs,
+		}
+		if prev.Request != nil {
+			hop["url"] = prev.Request.URL.String()
+		}
+
+		rec.mu.Lock()
+		rec.chain = append(rec.chain, hop)
+		rec.mu.Unlock()
+		return nil
+	}
+}
@@ -0,0 +1,62 @@
+package probe
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/PaesslerAG/jsonpath"
+)
+
+// checkExpectHeaders 对 expect 中每个 header 名按正则匹配响应头的值，返回未通过的描述列表。
+func checkExpectHeaders(headers http.Header, expect map[string]string) []string {
+	var failures []string
+	for name, pattern := range expect {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("header %q: invalid regex %q: %v", name, pattern, err))
+			continue
+		}
+		value := headers.Get(name)
+		if !re.MatchString(value) {
+			failures = append(failures, fmt.Sprintf("header %q value %q does not match %q", name, value, pattern))
+		}
+	}
+	return failures
+}
+
+// checkExpectJSONPath 对响应体按 JSON 解析后，用每个 JSONPath 表达式取值并与期望值做正则/字符串匹配。
+func checkExpectJSONPath(body []byte, expect map[string]string) []string {
+	if len(expect) == 0 {
+		return nil
+	}
+
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		failures := make([]string, 0, len(expect))
+		for path := range expect {
+			failures = append(failures, fmt.Sprintf("jsonpath %q: response body is not valid JSON: %v", path, err))
+		}
+		return failures
+	}
+
+	var failures []string
+	for path, pattern := range expect {
+		got, err := jsonpath.Get(path, doc)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("jsonpath %q: %v", path, err))
+			continue
+		}
+		gotStr := fmt.Sprintf("%v", got)
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("jsonpath %q: invalid regex %q: %v", path, pattern, err))
+			continue
+		}
+		if !re.MatchString(gotStr) {
+			failures = append(failures, fmt.Sprintf("jsonpath %q value %q does not match %q", path, gotStr, pattern))
+		}
+	}
+	return failures
+}
@@ -0,0 +1,416 @@
+package probe
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jarmAllZeros 是全部探测失败时返回的规范占位指纹。
+var jarmAllZeros = strings.Repeat("0", 62)
+
+// jarmProbeSpec 描述一次 JARM 探测使用的 ClientHello 排列组合。
+// 字段取值仿照 https://github.com/salesforce/jarm 的 10 组固定探测，但并不与其逐项相同。
+type jarmProbeSpec struct {
+	version     uint16 // 握手声明的 TLS 版本
+	cipherOrder string // forward / reverse / top_half / bottom_half / middle_out
+	grease      bool
+	alpnProtos  []string
+	extOrder    string // normal / reverse
+	supportVers []uint16
+}
+
+// jarmProbes 是固定的 10 组探测参数，顺序决定最终指纹的排列，不可更改。
+var jarmProbes = []jarmProbeSpec{
+	{version: tlsVersion12, cipherOrder: "forward", grease: false, alpnProtos: []string{"http/1.1", "h2"}, extOrder: "normal", supportVers: []uint16{tlsVersion12, tlsVersion13}},
+	{version: tlsVersion12, cipherOrder: "reverse", grease: false, alpnProtos: []string{"http/1.1", "h2"}, extOrder: "normal", supportVers: nil},
+	{version: tlsVersion12, cipherOrder: "top_half", grease: false, alpnProtos: []string{"http/1.1", "h2"}, extOrder: "normal", supportVers: nil},
+	{version: tlsVersion12, cipherOrder: "bottom_half", grease: false, alpnProtos: []string{"http/1.1"}, extOrder: "normal", supportVers: nil},
+	{version: tlsVersion12, cipherOrder: "middle_out", grease: true, alpnProtos: []string{"http/1.1"}, extOrder: "normal", supportVers: nil},
+	{version: tlsVersion11, cipherOrder: "forward", grease: false, alpnProtos: []string{"http/1.1", "h2"}, extOrder: "normal", supportVers: nil},
+	{version: tlsVersion13, cipherOrder: "forward", grease: false, alpnProtos: []string{"http/1.1", "h2"}, extOrder: "normal", supportVers: []uint16{tlsVersion13}},
+	{version: tlsVersion13, cipherOrder: "reverse", grease: false, alpnProtos: []string{"http/1.1", "h2"}, extOrder: "reverse", supportVers: []uint16{tlsVersion13}},
+	{version: tlsVersion13, cipherOrder: "bottom_half", grease: false, alpnProtos: []string{"http/1.1", "h2"}, extOrder: "normal", supportVers: []uint16{tlsVersion13}},
+	{version: tlsVersion13, cipherOrder: "forward", grease: false, alpnProtos: []string{"http/1.1"}, extOrder: "normal", supportVers: []uint16{tlsVersion12}},
+}
+
+const (
+	tlsVersion10 uint16 = 0x0301
+	tlsVersion11 uint16 = 0x0302
+	tlsVersion12 uint16 = 0x0303
+	tlsVersion13 uint16 = 0x0304
+)
+
+// jarmCipherSuites 是参与 JARM 握手排列的固定密码套件集合（按 forward 顺序）。
+var jarmCipherSuites = []uint16{
+	0x0016, 0xc013, 0xc014, 0x002f, 0x0033, 0x0035, 0x0039, 0x002c, 0x002e,
+	0xc00a, 0xc009, 0xc008, 0xc023, 0xc024, 0xc025, 0xc026, 0xc027, 0xc028,
+	0xc029, 0xc02a, 0xc02b, 0xc02c, 0xc02d, 0xc02e, 0xc02f, 0xc030, 0xc031,
+	0xc032, 0x009c, 0x009d, 0x009e, 0x009f, 0x00ba, 0x00bb, 0x00bc, 0x00bd,
+	0x00be, 0x00bf, 0x0041, 0x0067, 0x006b, 0x0084, 0x0088, 0x0096,
+}
+
+// jarmGreaseValue 是 RFC 8701 保留的 GREASE 取值之一，加在密码套件列表最前，用于探测服务端对未知密码套件的容忍度。
+const jarmGreaseValue uint16 = 0x0a0a
+
+// jarmProbeResult 保存单次探测的原始握手结果。
+type jarmProbeResult struct {
+	ok         bool
+	version    uint16
+	cipher     uint16
+	alpn       string
+	extensions string // 用于参与指纹计算的扩展顺序摘要
+	err        error
+}
+
+// jarmConnTracker 记录拨号成功后正在进行的原始连接，以便外层超时能强制关闭挂起的读写；
+// 尚在拨号中的连接不在其中，拨号本身只受 net.DialTimeout 的超时约束。
+type jarmConnTracker struct {
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+func newJarmConnTracker() *jarmConnTracker {
+	return &jarmConnTracker{conns: make(map[net.Conn]struct{})}
+}
+
+func (t *jarmConnTracker) add(c net.Conn) {
+	t.mu.Lock()
+	t.conns[c] = struct{}{}
+	t.mu.Unlock()
+}
+
+func (t *jarmConnTracker) remove(c net.Conn) {
+	t.mu.Lock()
+	delete(t.conns, c)
+	t.mu.Unlock()
+}
+
+func (t *jarmConnTracker) closeAll() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for c := range t.conns {
+		_ = c.Close()
+	}
+}
+
+// JARMProbe 对 Host:Port 执行 10 次手工构造的 TLS ClientHello 探测，并计算 62 位 JARM 指纹。
+// 指纹对 TLS 服务端实现（而非证书）的行为进行聚类，适合识别 C2/代理等同源基础设施。
+func JARMProbe(opts JARMOptions) Result {
+	toolName := opts.Tool
+	if toolName == "" {
+		toolName = "network.jarm"
+	}
+	if opts.TimeoutSec <= 0 {
+		opts.TimeoutSec = 10
+	}
+
+	addr := fmt.Sprintf("%s:%d", opts.Host, opts.Port)
+	tracker := newJarmConnTracker()
+
+	// 共享的强制关闭定时器：一旦超时触发，立刻关闭所有在途连接，避免探测互相拖慢。
+	timer := time.AfterFunc(time.Duration(opts.TimeoutSec)*time.Second, tracker.closeAll)
+	defer timer.Stop()
+
+	results := make([]jarmProbeResult, len(jarmProbes))
+	var wg sync.WaitGroup
+	for i, spec := range jarmProbes {
+		wg.Add(1)
+		go func(i int, spec jarmProbeSpec) {
+			defer wg.Done()
+			results[i] = runJarmProbe(addr, opts.Host, opts.TimeoutSec, spec, tracker)
+		}(i, spec)
+	}
+	wg.Wait()
+
+	fingerprint, anySuccess := buildJARMFingerprint(results)
+
+	probeDetails := make([]map[string]any, len(results))
+	for i, r := range results {
+		detail := map[string]any{"probe": i}
+		if r.ok {
+			detail["version"] = tlsVersionName(r.version)
+			detail["cipher"] = fmt.Sprintf("0x%04x", r.cipher)
+			detail["alpn"] = r.alpn
+			detail["extensions"] = r.extensions
+		} else if r.err != nil {
+			detail["error"] = r.err.Error()
+		}
+		probeDetails[i] = detail
+	}
+
+	return Result{
+		Success:  anySuccess,
+		Tool:     toolName,
+		Host:     opts.Host,
+		Port:     opts.Port,
+		Protocol: "jarm",
+		Details: map[string]any{
+			"jarm":   fingerprint,
+			"probes": probeDetails,
+		},
+	}
+}
+
+func runJarmProbe(addr, host string, timeoutSec int, spec jarmProbeSpec, tracker *jarmConnTracker) jarmProbeResult {
+	conn, err := net.DialTimeout("tcp", addr, time.Duration(timeoutSec)*time.Second)
+	if err != nil {
+		return jarmProbeResult{err: fmt.Errorf("dial failed: %w", err)}
+	}
+	tracker.add(conn)
+	defer tracker.remove(conn)
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(time.Duration(timeoutSec) * time.Second))
+
+	hello := buildJarmClientHello(spec, host)
+	if _, err := conn.Write(hello); err != nil {
+		return jarmProbeResult{err: fmt.Errorf("write client hello failed: %w", err)}
+	}
+
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return jarmProbeResult{err: fmt.Errorf("read server hello failed: %w", err)}
+	}
+
+	return parseJarmServerHello(buf[:n])
+}
+
+// buildJarmClientHello 手工拼装 TLS record + handshake 层的 ClientHello 字节，
+// 不经过 crypto/tls，从而能够精确控制密码套件/扩展顺序与 GREASE 值。
+func buildJarmClientHello(spec jarmProbeSpec, host string) []byte {
+	var ciphers []uint16
+	if spec.grease {
+		ciphers = append(ciphers, jarmGreaseValue)
+	}
+	ciphers = append(ciphers, orderCiphers(jarmCipherSuites, spec.cipherOrder)...)
+
+	cipherBytes := make([]byte, 0, len(ciphers)*2)
+	for _, c := range ciphers {
+		cipherBytes = append(cipherBytes, byte(c>>8), byte(c))
+	}
+
+	random := make([]byte, 32)
+	sessionID := make([]byte, 32)
+
+	extensions := buildJarmExtensions(spec, host)
+
+	handshake := []byte{}
+	handshake = appendUint16(handshake, spec.version) // client_version
+	handshake = append(handshake, random...)
+	handshake = append(handshake, byte(len(sessionID)))
+	handshake = append(handshake, sessionID...)
+	handshake = appendUint16(handshake, uint16(len(cipherBytes)))
+	handshake = append(handshake, cipherBytes...)
+	handshake = append(handshake, 0x01, 0x00) // compression methods: null only
+	handshake = appendUint16(handshake, uint16(len(extensions)))
+	handshake = append(handshake, extensions...)
+
+	body := []byte{0x01} // handshake type: client_hello
+	body = append(body, uint24(len(handshake))...)
+	body = append(body, handshake...)
+
+	record := []byte{0x16} // content type: handshake
+	record = appendUint16(record, tlsVersion10)
+	record = appendUint16(record, uint16(len(body)))
+	record = append(record, body...)
+	return record
+}
+
+func buildJarmExtensions(spec jarmProbeSpec, host string) []byte {
+	var exts [][]byte
+
+	// server_name：RFC 6066 要求 ServerNameList 至少有一项，空列表会被规范实现当作非法
+	// ClientHello 拒绝（fatal unexpected_message）。host 为 IP 或为空时直接省略该扩展。
+	if host != "" && net.ParseIP(host) == nil {
+		nameBytes := []byte(host)
+		entry := append([]byte{0x00}, appendUint16(nil, uint16(len(nameBytes)))...)
+		entry = append(entry, nameBytes...)
+		payload := appendUint16(nil, uint16(len(entry)))
+		payload = append(payload, entry...)
+		exts = append(exts, encodeExtension(0x0000, payload))
+	}
+
+	if len(spec.alpnProtos) > 0 {
+		var list []byte
+		for _, p := range spec.alpnProtos {
+			list = append(list, byte(len(p)))
+			list = append(list, []byte(p)...)
+		}
+		payload := appendUint16(nil, uint16(len(list)))
+		payload = append(payload, list...)
+		exts = append(exts, encodeExtension(0x0010, payload))
+	}
+
+	if len(spec.supportVers) > 0 {
+		payload := []byte{byte(len(spec.supportVers) * 2)}
+		for _, v := range spec.supportVers {
+			payload = appendUint16(payload, v)
+		}
+		exts = append(exts, encodeExtension(0x002b, payload))
+	}
+
+	if spec.extOrder == "reverse" {
+		for i, j := 0, len(exts)-1; i < j; i, j = i+1, j-1 {
+			exts[i], exts[j] = exts[j], exts[i]
+		}
+	}
+
+	var out []byte
+	for _, e := range exts {
+		out = append(out, e...)
+	}
+	return out
+}
+
+func encodeExtension(id uint16, payload []byte) []byte {
+	e := appendUint16(nil, id)
+	e = appendUint16(e, uint16(len(payload)))
+	e = append(e, payload...)
+	return e
+}
+
+// orderCiphers 按探测规格要求的顺序重排固定密码套件列表。
+func orderCiphers(base []uint16, order string) []uint16 {
+	out := make([]uint16, len(base))
+	copy(out, base)
+	switch order {
+	case "reverse":
+		for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+			out[i], out[j] = out[j], out[i]
+		}
+	case "top_half":
+		out = out[:len(out)/2]
+	case "bottom_half":
+		out = out[len(out)/2:]
+	case "middle_out":
+		mid := len(out) / 2
+		reordered := make([]uint16, 0, len(out))
+		reordered = append(reordered, out[mid])
+		for i := 1; mid-i >= 0 || mid+i < len(out); i++ {
+			if mid+i < len(out) {
+				reordered = append(reordered, out[mid+i])
+			}
+			if mid-i >= 0 {
+				reordered = append(reordered, out[mid-i])
+			}
+		}
+		out = reordered
+	}
+	return out
+}
+
+// parseJarmServerHello 从原始 TLS record 中提取 ServerHello 选中的版本/密码套件/ALPN。
+func parseJarmServerHello(data []byte) jarmProbeResult {
+	if len(data) < 5 || data[0] != 0x16 {
+		return jarmProbeResult{err: fmt.Errorf("not a TLS handshake record")}
+	}
+	body := data[5:]
+	if len(body) < 4 || body[0] != 0x02 {
+		return jarmProbeResult{err: fmt.Errorf("not a server hello message")}
+	}
+	hs := body[4:]
+	if len(hs) < 2+32+1 {
+		return jarmProbeResult{err: fmt.Errorf("server hello truncated")}
+	}
+	version := binary.BigEndian.Uint16(hs[0:2])
+	pos := 2 + 32
+	sessionIDLen := int(hs[pos])
+	pos += 1 + sessionIDLen
+	if pos+2 > len(hs) {
+		return jarmProbeResult{err: fmt.Errorf("server hello truncated at cipher")}
+	}
+	cipher := binary.BigEndian.Uint16(hs[pos : pos+2])
+	pos += 2 + 1 // cipher + compression method
+
+	alpn := ""
+	var extOrder []string
+	if pos+2 <= len(hs) {
+		extLen := int(binary.BigEndian.Uint16(hs[pos : pos+2]))
+		pos += 2
+		end := pos + extLen
+		if end > len(hs) {
+			end = len(hs)
+		}
+		for pos+4 <= end {
+			extID := binary.BigEndian.Uint16(hs[pos : pos+2])
+			extSize := int(binary.BigEndian.Uint16(hs[pos+2 : pos+4]))
+			extData := hs[pos+4 : min(pos+4+extSize, end)]
+			extOrder = append(extOrder, fmt.Sprintf("%04x", extID))
+			if extID == 0x0010 && len(extData) > 3 {
+				protoLen := int(extData[2])
+				if 3+protoLen <= len(extData) {
+					alpn = string(extData[3 : 3+protoLen])
+				}
+			}
+			pos += 4 + extSize
+		}
+	}
+
+	return jarmProbeResult{ok: true, version: version, cipher: cipher, alpn: alpn, extensions: strings.Join(extOrder, "-")}
+}
+
+// buildJARMFingerprint 拼接 10 次探测结果并哈希为 62 位指纹：前 30 位取"密码套件|版本|ALPN"字段拼接串
+// 的 SHA-256 截断，后 32 位取扩展字段拼接串的 SHA-256 截断。
+// 失败的探测以空字符串参与拼接；全部失败时返回规范的全零指纹。
+func buildJARMFingerprint(results []jarmProbeResult) (string, bool) {
+	cipherVersionFields := make([]string, len(results))
+	extensionFields := make([]string, len(results))
+	anySuccess := false
+
+	for i, r := range results {
+		if !r.ok {
+			continue
+		}
+		anySuccess = true
+		cipherVersionFields[i] = fmt.Sprintf("%04x|%04x|%s", r.cipher, r.version, r.alpn)
+		extensionFields[i] = r.extensions
+	}
+
+	if !anySuccess {
+		return jarmAllZeros, false
+	}
+
+	cvSum := sha256.Sum256([]byte(strings.Join(cipherVersionFields, ",")))
+	extSum := sha256.Sum256([]byte(strings.Join(extensionFields, ",")))
+
+	cvHex := hex.EncodeToString(cvSum[:])[:30]
+	extHex := hex.EncodeToString(extSum[:])[:32]
+	return cvHex + extHex, true
+}
+
+func tlsVersionName(v uint16) string {
+	switch v {
+	case tlsVersion10:
+		return "tls1.0"
+	case tlsVersion11:
+		return "tls1.1"
+	case tlsVersion12:
+		return "tls1.2"
+	case tlsVersion13:
+		return "tls1.3"
+	default:
+		return fmt.Sprintf("0x%04x", v)
+	}
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+func uint24(v int) []byte {
+	return []byte{byte(v >> 16), byte(v >> 8), byte(v)}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -3,8 +3,6 @@ package probe
 import (
 	"fmt"
 	"os/exec"
-	"regexp"
-	"strings"
 )
 
 func Mtr(opts MtrOptions) Result {
@@ -43,7 +41,10 @@ func Mtr(opts MtrOptions) Result {
 	}
 
 	if cmdResult != nil {
-		hops := extractHops(cmdResult.Stdout)
+		hops := parseMtrHops(cmdResult.Stdout)
+		if opts.EnrichASN {
+			enrichMtrASN(hops, opts.TimeoutSec)
+		}
 		result.Summary["hops"] = hops
 		result.Summary["total_hops"] = len(hops)
 	}
@@ -61,21 +62,3 @@ func Mtr(opts MtrOptions) Result {
 	result.Error = err.Error()
 	return result
 }
-
-func extractHops(output string) []map[string]string {
-	var hops []map[string]string
-	lines := strings.Split(output, "\n")
-	re := regexp.MustCompile(`^\s*(\d+)\.\s+(\S+)\s+(\S+)%\s+`)
-	for _, line := range lines {
-		m := re.FindStringSubmatch(line)
-		if len(m) >= 4 {
-			hop := map[string]string{
-				"hop":          m[1],
-				"host":         m[2],
-				"loss_percent": m[3],
-			}
-			hops = append(hops, hop)
-		}
-	}
-	return hops
-}
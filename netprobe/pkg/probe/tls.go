@@ -117,6 +117,18 @@ func TLSProbe(opts TLSOptions) Result {
 		details["cert_issuer"] = cert.Issuer.String()
 		details["cert_not_before"] = cert.NotBefore
 		details["cert_not_after"] = cert.NotAfter
+
+		certChain := make([]map[string]any, len(state.PeerCertificates))
+		for i, c := range state.PeerCertificates {
+			certChain[i] = certIntel(c)
+		}
+		details["cert_chain"] = certChain
+
+		if opts.OutputCertDir != "" {
+			if err := writeCertCorpus(opts.OutputCertDir, state.PeerCertificates); err != nil {
+				details["cert_corpus_error"] = err.Error()
+			}
+		}
 	}
 
 	return Result{
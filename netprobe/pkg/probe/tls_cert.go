@@ -0,0 +1,151 @@
+package probe
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/md5"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/glaslos/tlsh"
+)
+
+// certIntel 提炼单张证书的被动指纹情报，供 TLSProbe 写入 Result.Details 或落盘语料库。
+func certIntel(cert *x509.Certificate) map[string]any {
+	sha1Sum := sha1.Sum(cert.Raw)
+	sha256Sum := sha256.Sum256(cert.Raw)
+	md5Sum := md5.Sum(cert.Raw)
+
+	intel := map[string]any{
+		"sha1":           hex.EncodeToString(sha1Sum[:]),
+		"sha256":         hex.EncodeToString(sha256Sum[:]),
+		"md5":            hex.EncodeToString(md5Sum[:]),
+		"serial_hex":     cert.SerialNumber.Text(16),
+		"signature_algo": cert.SignatureAlgorithm.String(),
+		"subject":        cert.Subject.String(),
+		"issuer":         cert.Issuer.String(),
+		"subject_rdn":    rdnMap(cert.Subject),
+		"issuer_rdn":     rdnMap(cert.Issuer),
+		"not_before":     cert.NotBefore,
+		"not_after":      cert.NotAfter,
+		"sans":           certSANs(cert),
+	}
+
+	if algo, bits := certKeyInfo(cert); algo != "" {
+		intel["key_algo"] = algo
+		intel["key_bits"] = bits
+	}
+
+	if h, err := tlsh.HashBytes(cert.Raw); err == nil {
+		intel["tlsh"] = h.String()
+	}
+
+	return intel
+}
+
+// rdnMap 把 pkix.Name 摊平为常见 RDN 字段的键值映射，方便下游直接按字段过滤。
+func rdnMap(name pkix.Name) map[string]any {
+	m := map[string]any{}
+	if name.CommonName != "" {
+		m["CN"] = name.CommonName
+	}
+	if len(name.Organization) > 0 {
+		m["O"] = name.Organization
+	}
+	if len(name.OrganizationalUnit) > 0 {
+		m["OU"] = name.OrganizationalUnit
+	}
+	if len(name.Country) > 0 {
+		m["C"] = name.Country
+	}
+	if len(name.Province) > 0 {
+		m["ST"] = name.Province
+	}
+	if len(name.Locality) > 0 {
+		m["L"] = name.Locality
+	}
+	return m
+}
+
+// certSANs 收集证书中出现的全部 Subject Alternative Name 类型。
+func certSANs(cert *x509.Certificate) map[string]any {
+	sans := map[string]any{}
+	if len(cert.DNSNames) > 0 {
+		sans["dns"] = cert.DNSNames
+	}
+	if len(cert.IPAddresses) > 0 {
+		ips := make([]string, len(cert.IPAddresses))
+		for i, ip := range cert.IPAddresses {
+			ips[i] = ip.String()
+		}
+		sans["ip"] = ips
+	}
+	if len(cert.URIs) > 0 {
+		uris := make([]string, len(cert.URIs))
+		for i, u := range cert.URIs {
+			uris[i] = u.String()
+		}
+		sans["uri"] = uris
+	}
+	if len(cert.EmailAddresses) > 0 {
+		sans["email"] = cert.EmailAddresses
+	}
+	return sans
+}
+
+// certKeyInfo 返回公钥算法名称及位宽（或曲线/密钥长度），未知类型返回空字符串。
+func certKeyInfo(cert *x509.Certificate) (string, int) {
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return "RSA", pub.N.BitLen()
+	case *ecdsa.PublicKey:
+		return "ECDSA", pub.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return "Ed25519", len(pub) * 8
+	default:
+		return "", 0
+	}
+}
+
+// writeCertCorpus 把叶子证书及其链上的每张证书写入 dir，按 SHA-256 去重：
+// <sha256>.pem 保存原始 DER（PEM 编码），<sha256>.json 保存解析后的情报，便于持续积累语料。
+func writeCertCorpus(dir string, chain []*x509.Certificate) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create cert dir failed: %w", err)
+	}
+
+	for _, cert := range chain {
+		sum := sha256.Sum256(cert.Raw)
+		hash := hex.EncodeToString(sum[:])
+		pemPath := filepath.Join(dir, hash+".pem")
+		jsonPath := filepath.Join(dir, hash+".json")
+
+		if _, err := os.Stat(pemPath); err == nil {
+			continue // 已存在，去重跳过
+		}
+
+		block := &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}
+		if err := os.WriteFile(pemPath, pem.EncodeToMemory(block), 0o644); err != nil {
+			return fmt.Errorf("write cert pem failed: %w", err)
+		}
+
+		intel := certIntel(cert)
+		buf, err := json.MarshalIndent(intel, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal cert intel failed: %w", err)
+		}
+		if err := os.WriteFile(jsonPath, buf, 0o644); err != nil {
+			return fmt.Errorf("write cert json failed: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package probe
+
+import (
+	"crypto/tls"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+// TestTLSEnumerateCombosExcludeTLS13 guards against the regression where
+// tls.Config.CipherSuites (only effective for TLS 1.0-1.2) was used to
+// build per-cipher rows for TLS 1.3 too, which always silently no-ops and
+// makes every TLS1.2-only cipher falsely report as TLS1.3-supported.
+func TestTLSEnumerateCombosExcludeTLS13(t *testing.T) {
+	combos := buildTLSEnumerateCombos()
+	if len(combos) == 0 {
+		t.Fatalf("expected at least one TLS 1.0-1.2 combo")
+	}
+	for _, combo := range combos {
+		if combo.version == tls.VersionTLS13 {
+			t.Fatalf("combo list must not include TLS1.3: cipher 0x%04x was paired with TLS1.3, but Config.CipherSuites is ignored for that version", combo.cipher)
+		}
+	}
+}
+
+func TestTLSEnumerateAgainstLocalServer(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	// 绝大多数密码套件/版本组合对这个测试服务器来说都是预期中的被拒绝组合，
+	// 默认的 http.Server 错误日志会把每一次握手失败都打到 stderr，这里静音掉。
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("parse test server URL: %v", err)
+	}
+	host, portStr, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		t.Fatalf("split test server host:port: %v", err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatalf("parse test server port: %v", err)
+	}
+
+	result := TLSEnumerate(TLSEnumerateOptions{Host: host, Port: port, TimeoutSec: 2, Parallel: 8})
+	if !result.Success {
+		t.Fatalf("expected TLSEnumerate to succeed, got %+v", result)
+	}
+
+	supported, _ := result.Details["supported"].([]map[string]any)
+	rejected, _ := result.Details["rejected"].([]map[string]any)
+
+	tls13Count := 0
+	for _, row := range append(append([]map[string]any{}, supported...), rejected...) {
+		if row["version"] == "tls1.3" {
+			tls13Count++
+		}
+	}
+	if tls13Count != 1 {
+		t.Fatalf("expected exactly one TLS1.3 entry (a single version probe, not a per-cipher matrix), got %d", tls13Count)
+	}
+
+	found13 := false
+	for _, row := range supported {
+		if row["version"] == "tls1.3" {
+			found13 = true
+			if row["cipher"] == nil || row["cipher"] == "" {
+				t.Fatalf("expected the TLS1.3 entry to record the negotiated cipher, got %+v", row)
+			}
+		}
+	}
+	if !found13 {
+		t.Fatalf("expected the local httptest TLS server to support TLS1.3, supported=%+v rejected=%+v", supported, rejected)
+	}
+}
@@ -0,0 +1,107 @@
+package probe
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// SNIProbeOptions 描述一次 SNI 路由探测：对同一 Host:Port 用多个候选 SNI 分别握手，
+// 用于发现基于 SNI 分流的虚拟主机 TLS 后端（SNI-based router）。
+type SNIProbeOptions struct {
+	Host       string
+	Port       int
+	SNIs       []string
+	TimeoutSec int
+	Insecure   bool
+	Tool       string
+}
+
+// TLSSNIProbe 对每个候选 SNI 独立建立 TLS 连接，记录证书主体/SAN、证书链哈希、ALPN、密码套件，
+// 以及服务端是否返回 unrecognized_name 致命告警。仅当全部 SNI 都成功握手（无致命告警）时 Success=true。
+func TLSSNIProbe(opts SNIProbeOptions) Result {
+	toolName := opts.Tool
+	if toolName == "" {
+		toolName = "network.tls-probe-sni"
+	}
+	if opts.TimeoutSec <= 0 {
+		opts.TimeoutSec = 10
+	}
+
+	addr := fmt.Sprintf("%s:%d", opts.Host, opts.Port)
+
+	matrix := make([]map[string]any, len(opts.SNIs))
+	allOK := true
+
+	for i, sni := range opts.SNIs {
+		row, ok := probeOneSNI(addr, sni, opts)
+		matrix[i] = row
+		if !ok {
+			allOK = false
+		}
+	}
+
+	return Result{
+		Success:  allOK && len(opts.SNIs) > 0,
+		Tool:     toolName,
+		Host:     opts.Host,
+		Port:     opts.Port,
+		Protocol: "tls",
+		Details: map[string]any{
+			"sni_matrix": matrix,
+		},
+	}
+}
+
+func probeOneSNI(addr, sni string, opts SNIProbeOptions) (map[string]any, bool) {
+	row := map[string]any{"sni": sni}
+
+	tlsCfg := &tls.Config{
+		ServerName:         sni,
+		InsecureSkipVerify: opts.Insecure,
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: time.Duration(opts.TimeoutSec) * time.Second},
+		Config:    tlsCfg,
+	}
+
+	conn, err := dialer.Dial("tcp", addr)
+	if err != nil {
+		row["error"] = err.Error()
+		row["unrecognized_name"] = strings.Contains(err.Error(), "unrecognized name")
+		return row, false
+	}
+	defer conn.Close()
+
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		row["error"] = "connection is not TLS"
+		return row, false
+	}
+
+	state := tlsConn.ConnectionState()
+	row["alpn"] = state.NegotiatedProtocol
+	if state.CipherSuite != 0 {
+		row["cipher"] = tls.CipherSuiteName(state.CipherSuite)
+	}
+
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		row["cert_subject"] = cert.Subject.String()
+		row["cert_sans"] = certSANs(cert)
+
+		h := sha256.New()
+		for _, c := range state.PeerCertificates {
+			h.Write(c.Raw)
+		}
+		row["chain_hash"] = hex.EncodeToString(h.Sum(nil))
+	}
+
+	row["unrecognized_name"] = false
+	return row, true
+}
@@ -37,10 +37,18 @@ func Traceroute(opts TraceOptions) Result {
 		Target:    opts.Target,
 		MaxHops:   opts.MaxHops,
 		RawOutput: "",
+		Summary:   map[string]any{},
 	}
 
 	if cmdResult != nil {
 		result.RawOutput = TrimOutput(cmdResult.Stdout, 8000)
+
+		hops := parseTraceHops(cmdResult.Stdout, runtime.GOOS == "windows")
+		if opts.EnrichASN {
+			enrichTraceASN(hops, opts.TimeoutSec)
+		}
+		result.Summary["hops"] = hops
+		result.Summary["total_hops"] = len(hops)
 	}
 
 	if err == nil {
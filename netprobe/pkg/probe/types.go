@@ -33,6 +33,7 @@ type TraceOptions struct {
 	Target     string
 	MaxHops    int
 	TimeoutSec int
+	EnrichASN  bool
 	Tool       string
 }
 
@@ -41,6 +42,7 @@ type MtrOptions struct {
 	Count        int
 	ReportCycles int
 	TimeoutSec   int
+	EnrichASN    bool
 	Tool         string
 }
 
@@ -68,16 +70,39 @@ type TLSOptions struct {
 	CACert     string
 	ClientCert string
 	ClientKey  string
+	// OutputCertDir 非空时，将链上每张证书以 <sha256>.pem + <sha256>.json 落盘去重保存。
+	OutputCertDir string
+	Tool          string
+}
+
+type TLSEnumerateOptions struct {
+	Host       string
+	Port       int
+	TimeoutSec int
+	Parallel   int
+	Tool       string
+}
+
+type JARMOptions struct {
+	Host       string
+	Port       int
+	TimeoutSec int
 	Tool       string
 }
 
 type HTTPOptions struct {
-	URL            string
-	Method         string
-	Headers        map[string]string
-	Body           string
-	TimeoutSec     int
+	URL        string
+	Method     string
+	Headers    map[string]string
+	Body       string
+	TimeoutSec int
+	// Protocol 强制指定传输协议："h1"、"h2"、"h3"；为空时使用标准库的 h1/h2 自动协商。
+	Protocol       string
 	ExpectStatus   int
 	ExpectContains string
+	// ExpectHeader 的 key 是响应头名，value 是必须匹配响应头值的正则表达式。
+	ExpectHeader map[string]string
+	// ExpectJSONPath 的 key 是 JSONPath 表达式，value 是必须匹配取值（字符串化后）的正则表达式。
+	ExpectJSONPath map[string]string
 	Tool           string
 }